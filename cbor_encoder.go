@@ -0,0 +1,236 @@
+package zap
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sync"
+	"time"
+)
+
+// CBOR major types, per RFC 7049 section 2.1.
+const (
+	cborMajorUint     byte = 0x00
+	cborMajorNegInt   byte = 0x20
+	cborMajorBytes    byte = 0x40
+	cborMajorText     byte = 0x60
+	cborMajorArray    byte = 0x80
+	cborMajorMap      byte = 0xA0
+	cborMajorTag      byte = 0xC0
+	cborMajorSimple   byte = 0xE0
+	cborIndefiniteMap byte = 0xBF
+	cborBreak         byte = 0xFF
+	cborFalse         byte = 0xF4
+	cborTrue          byte = 0xF5
+	cborNil           byte = 0xF6
+	cborFloat32       byte = 0xFA
+	cborFloat64       byte = 0xFB
+	cborTagRFC3339    byte = 0xC0 // tag(0): standard date/time string
+)
+
+var cborPool = sync.Pool{
+	New: func() interface{} {
+		return &cborEncoder{
+			bytes: make([]byte, 0, _initialBufSize),
+		}
+	},
+}
+
+// cborEncoder encodes log entries as CBOR (RFC 7049) indefinite-length maps,
+// mirroring the binary_log build tag zerolog exposes for throughput- and
+// size-sensitive callers. It implements the same Encoder interface as
+// logfmtEncoder and pools its underlying buffer the same way.
+type cborEncoder struct {
+	bytes []byte
+}
+
+func newCBOREncoder() *cborEncoder {
+	enc := cborPool.Get().(*cborEncoder)
+	enc.truncate()
+	return enc
+}
+
+func (enc *cborEncoder) truncate() {
+	enc.bytes = enc.bytes[:0]
+}
+
+// Clone copies the current encoder, including any data already encoded.
+func (enc *cborEncoder) Clone() Encoder {
+	clone := cborPool.Get().(*cborEncoder)
+	clone.truncate()
+	clone.bytes = append(clone.bytes, enc.bytes...)
+	return clone
+}
+
+func (enc *cborEncoder) Free() {
+	cborPool.Put(enc)
+}
+
+// WriteEntry writes a complete log message to the supplied writer: a single
+// indefinite-length CBOR map (major type 5, head 0xBF) containing level,
+// msg, ts, and the encoder's accumulated fields, closed with the break byte
+// 0xFF. It doesn't modify or lock the encoder's underlying byte slice, so
+// it's safe to call from multiple goroutines, but it's not safe to call
+// WriteEntry while adding fields.
+func (enc *cborEncoder) WriteEntry(sink io.Writer, msg string, lvl Level, t time.Time) error {
+	final := cborPool.Get().(*cborEncoder)
+	final.truncate()
+	final.bytes = append(final.bytes, cborIndefiniteMap)
+	final.addLevel(lvl)
+	final.AddString("msg", msg)
+	final.addTime(t)
+	final.bytes = append(final.bytes, enc.bytes...)
+	final.bytes = append(final.bytes, cborBreak)
+
+	expectedBytes := len(final.bytes)
+	n, err := sink.Write(final.bytes)
+	final.Free()
+	if err != nil {
+		return err
+	}
+	if n != expectedBytes {
+		return fmt.Errorf("incomplete write: only wrote %v of %v bytes", n, expectedBytes)
+	}
+	return nil
+}
+
+// addHead writes a CBOR type/length head: the major type ORed with the
+// immediate small value, or a following 1/2/4/8-byte length for larger
+// values, per the rules in RFC 7049 section 2.1.
+func (enc *cborEncoder) addHead(major byte, n uint64) {
+	switch {
+	case n < 24:
+		enc.bytes = append(enc.bytes, major|byte(n))
+	case n <= math.MaxUint8:
+		enc.bytes = append(enc.bytes, major|24, byte(n))
+	case n <= math.MaxUint16:
+		enc.bytes = append(enc.bytes, major|25, byte(n>>8), byte(n))
+	case n <= math.MaxUint32:
+		enc.bytes = append(enc.bytes, major|26, byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+	default:
+		enc.bytes = append(enc.bytes, major|27,
+			byte(n>>56), byte(n>>48), byte(n>>40), byte(n>>32),
+			byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+	}
+}
+
+func (enc *cborEncoder) addKey(key string) {
+	enc.addHead(cborMajorText, uint64(len(key)))
+	enc.bytes = append(enc.bytes, key...)
+}
+
+// AddBool appends the key and bool value to the encoder's map.
+func (enc *cborEncoder) AddBool(key string, value bool) {
+	enc.addKey(key)
+	if value {
+		enc.bytes = append(enc.bytes, cborTrue)
+	} else {
+		enc.bytes = append(enc.bytes, cborFalse)
+	}
+}
+
+// AddFloat64 appends the key and float64 value, encoded as an IEEE-754
+// double (major 7, head 0xFB).
+func (enc *cborEncoder) AddFloat64(key string, value float64) {
+	enc.addKey(key)
+	enc.bytes = append(enc.bytes, cborFloat64)
+	bits := math.Float64bits(value)
+	enc.bytes = append(enc.bytes,
+		byte(bits>>56), byte(bits>>48), byte(bits>>40), byte(bits>>32),
+		byte(bits>>24), byte(bits>>16), byte(bits>>8), byte(bits))
+}
+
+// AddInt appends the key and int value to the encoder's map.
+func (enc *cborEncoder) AddInt(key string, value int) {
+	enc.AddInt64(key, int64(value))
+}
+
+// AddInt64 appends the key and int64 value, using major type 0 (unsigned)
+// for non-negative values and major type 1 (negative) otherwise.
+func (enc *cborEncoder) AddInt64(key string, value int64) {
+	enc.addKey(key)
+	if value >= 0 {
+		enc.addHead(cborMajorUint, uint64(value))
+	} else {
+		enc.addHead(cborMajorNegInt, uint64(-1-value))
+	}
+}
+
+// AddUint appends the key and uint value to the encoder's map.
+func (enc *cborEncoder) AddUint(key string, value uint) {
+	enc.AddUint64(key, uint64(value))
+}
+
+// AddUint64 appends the key and uint64 value, using major type 0.
+func (enc *cborEncoder) AddUint64(key string, value uint64) {
+	enc.addKey(key)
+	enc.addHead(cborMajorUint, value)
+}
+
+// AddUintptr appends the key and uintptr value, using major type 0.
+func (enc *cborEncoder) AddUintptr(key string, value uintptr) {
+	enc.AddUint64(key, uint64(value))
+}
+
+// AddMarshaler adds a LogMarshaler's fields as a nested indefinite-length
+// map (unlike logfmt, CBOR supports real nesting), so MarshalLog
+// implementations can recurse through AddObject/AddMarshaler freely.
+func (enc *cborEncoder) AddMarshaler(key string, obj LogMarshaler) error {
+	enc.addKey(key)
+	enc.bytes = append(enc.bytes, cborIndefiniteMap)
+	err := obj.MarshalLog(enc)
+	enc.bytes = append(enc.bytes, cborBreak)
+	return err
+}
+
+// AddObject encodes value as its "%+v" text representation, wrapped in a
+// CBOR text string (major type 3); it does not produce nested CBOR
+// structure. Callers that need real structure should implement
+// LogMarshaler and use AddMarshaler instead.
+func (enc *cborEncoder) AddObject(key string, value interface{}) error {
+	enc.AddString(key, fmt.Sprintf("%+v", value))
+	return nil
+}
+
+// AddString appends the key and string value to the encoder's map as a
+// CBOR text string (major type 3).
+func (enc *cborEncoder) AddString(key, value string) {
+	enc.addKey(key)
+	enc.addHead(cborMajorText, uint64(len(value)))
+	enc.bytes = append(enc.bytes, value...)
+}
+
+func (enc *cborEncoder) addLevel(lvl Level) {
+	enc.addKey("level")
+	var s string
+	switch lvl {
+	case DebugLevel:
+		s = "debug"
+	case InfoLevel:
+		s = "info"
+	case WarnLevel:
+		s = "warn"
+	case ErrorLevel:
+		s = "error"
+	case PanicLevel:
+		s = "panic"
+	case FatalLevel:
+		s = "fatal"
+	default:
+		enc.addHead(cborMajorUint, uint64(lvl))
+		return
+	}
+	enc.addHead(cborMajorText, uint64(len(s)))
+	enc.bytes = append(enc.bytes, s...)
+}
+
+// addTime appends the ts key as CBOR tag 0 (standard date/time string),
+// wrapping an RFC3339 text string, so generic CBOR decoders recognize it as
+// a timestamp without needing to know this package's field layout.
+func (enc *cborEncoder) addTime(t time.Time) {
+	enc.addKey("ts")
+	enc.bytes = append(enc.bytes, cborTagRFC3339)
+	s := t.Format(time.RFC3339)
+	enc.addHead(cborMajorText, uint64(len(s)))
+	enc.bytes = append(enc.bytes, s...)
+}
@@ -0,0 +1,162 @@
+package zap
+
+import (
+	"bytes"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// cborTestDecoder is a deliberately minimal reader for the subset of CBOR
+// that cborEncoder emits: indefinite-length maps of text-string keys to
+// unsigned/negative ints, floats, bools, nil, or text strings. It exists so
+// these tests don't need an external CBOR library; for anything beyond
+// round-tripping our own output, github.com/fxamacker/cbor decodes this
+// encoder's output correctly, since it only uses well-formed, standard CBOR
+// major types.
+type cborTestDecoder struct {
+	b   []byte
+	pos int
+}
+
+func (d *cborTestDecoder) byte() byte {
+	b := d.b[d.pos]
+	d.pos++
+	return b
+}
+
+func (d *cborTestDecoder) uintValue(info byte) uint64 {
+	switch {
+	case info < 24:
+		return uint64(info)
+	case info == 24:
+		v := uint64(d.byte())
+		return v
+	case info == 25:
+		v := uint64(d.byte())<<8 | uint64(d.byte())
+		return v
+	case info == 26:
+		var v uint64
+		for i := 0; i < 4; i++ {
+			v = v<<8 | uint64(d.byte())
+		}
+		return v
+	default:
+		var v uint64
+		for i := 0; i < 8; i++ {
+			v = v<<8 | uint64(d.byte())
+		}
+		return v
+	}
+}
+
+// decodeMap reads one indefinite-length map (the head byte must already be
+// consumed by the caller) and returns its entries as a generic map.
+func (d *cborTestDecoder) decodeMap() map[string]interface{} {
+	out := map[string]interface{}{}
+	for d.b[d.pos] != cborBreak {
+		key := d.decodeOne().(string)
+		out[key] = d.decodeOne()
+	}
+	d.pos++ // consume the break byte
+	return out
+}
+
+func (d *cborTestDecoder) decodeOne() interface{} {
+	head := d.byte()
+	major := head & 0xE0
+	info := head & 0x1F
+
+	switch {
+	case head == cborIndefiniteMap:
+		return d.decodeMap()
+	case head == cborTagRFC3339:
+		return d.decodeOne()
+	case head == cborFalse:
+		return false
+	case head == cborTrue:
+		return true
+	case head == cborNil:
+		return nil
+	case head == cborFloat64:
+		var bits uint64
+		for i := 0; i < 8; i++ {
+			bits = bits<<8 | uint64(d.byte())
+		}
+		return math.Float64frombits(bits)
+	case major == cborMajorUint:
+		return d.uintValue(info)
+	case major == cborMajorNegInt:
+		return -1 - int64(d.uintValue(info))
+	case major == cborMajorText:
+		n := d.uintValue(info)
+		s := string(d.b[d.pos : d.pos+int(n)])
+		d.pos += int(n)
+		return s
+	default:
+		panic("cborTestDecoder: unsupported head byte")
+	}
+}
+
+func decodeCBOREntry(t *testing.T, raw []byte) map[string]interface{} {
+	d := &cborTestDecoder{b: raw}
+	head := d.byte()
+	assert.Equal(t, cborIndefiniteMap, head, "entry should open with an indefinite-length map")
+	return d.decodeMap()
+}
+
+func TestCBOREncoderWriteEntry(t *testing.T) {
+	enc := newCBOREncoder()
+	enc.AddString("k", "v")
+	enc.AddInt64("count", -7)
+	enc.AddBool("ok", true)
+
+	var buf bytes.Buffer
+	assert.NoError(t, enc.WriteEntry(&buf, "hello", InfoLevel, time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)))
+	enc.Free()
+
+	got := decodeCBOREntry(t, buf.Bytes())
+	assert.Equal(t, "info", got["level"])
+	assert.Equal(t, "hello", got["msg"])
+	assert.Equal(t, "v", got["k"])
+	assert.Equal(t, int64(-7), got["count"])
+	assert.Equal(t, true, got["ok"])
+}
+
+func TestCBOREncoderFields(t *testing.T) {
+	tests := []struct {
+		desc     string
+		key      string
+		expected interface{}
+		f        func(Encoder)
+	}{
+		{"string", "k", "v", func(e Encoder) { e.AddString("k", "v") }},
+		{"bool true", "k", true, func(e Encoder) { e.AddBool("k", true) }},
+		{"bool false", "k", false, func(e Encoder) { e.AddBool("k", false) }},
+		// CBOR's major type 0 doesn't distinguish int64 from uint64 for
+		// non-negative values, so decodeOne reports uint64 here; only
+		// negative values (major type 1) decode back out as int64.
+		{"int64", "k", uint64(42), func(e Encoder) { e.AddInt64("k", 42) }},
+		{"negative int64", "k", int64(-42), func(e Encoder) { e.AddInt64("k", -42) }},
+		{"uint64", "k", uint64(42), func(e Encoder) { e.AddUint64("k", 42) }},
+		{"float64", "k", 1.5, func(e Encoder) { e.AddFloat64("k", 1.5) }},
+		{"marshaler", "k", map[string]interface{}{"loggable": "yes"}, func(e Encoder) {
+			assert.NoError(t, e.AddMarshaler("k", loggable{true}))
+		}},
+	}
+
+	for _, tt := range tests {
+		enc := newCBOREncoder()
+		tt.f(enc)
+
+		d := &cborTestDecoder{b: append([]byte{cborIndefiniteMap}, enc.bytes...)}
+		d.b = append(d.b, cborBreak)
+		d.pos++ // consume the indefinite-map head byte decodeMap expects already gone
+		got := d.decodeMap()
+
+		assert.Equal(t, tt.expected, got[tt.key], "Unexpected CBOR value after adding a %s.", tt.desc)
+		enc.Free()
+	}
+}
@@ -0,0 +1,337 @@
+package zap
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+const _defaultConsoleTimeFormat = "15:04:05.000"
+
+var consolePool = sync.Pool{
+	New: func() interface{} {
+		return &consoleEncoder{
+			bytes: make([]byte, 0, _initialBufSize),
+		}
+	},
+}
+
+// ANSI color codes for the level tag. Order matches the levels they color.
+const (
+	_ansiReset  = "\x1b[0m"
+	_ansiBold   = "\x1b[1m"
+	_ansiCyan   = "\x1b[36m"
+	_ansiGreen  = "\x1b[32m"
+	_ansiYellow = "\x1b[33m"
+	_ansiRed    = "\x1b[31m"
+)
+
+// ConsoleEncoderOptions configures a consoleEncoder. The zero value uses
+// color only when stderr looks like a TTY, the default timestamp layout,
+// and no special key ordering.
+type ConsoleEncoderOptions struct {
+	// Color forces color on or off. If nil, color is auto-detected via
+	// isatty against os.Stderr.
+	Color *bool
+	// TimeFormat is passed to time.Time.Format for the humanized
+	// timestamp. Defaults to "15:04:05.000".
+	TimeFormat string
+	// KeyOrder lists field keys that should surface first, in order, ahead
+	// of the remaining fields (which keep their original order).
+	KeyOrder []string
+}
+
+// consoleEncoder implements the Encoder interface but, at WriteEntry time,
+// formats output for a human reading a terminal: a colorized level tag, a
+// humanized timestamp, the message in bold, then key=value pairs with
+// common fields (per KeyOrder) surfaced first. Fields are still appended
+// through the usual AddString/AddInt64/... methods, so existing call sites
+// work without modification; only WriteEntry's rendering differs from
+// logfmtEncoder.
+type consoleEncoder struct {
+	opts ConsoleEncoderOptions
+
+	bytes []byte
+	// keys/values records fields in the order they were added, since
+	// KeyOrder needs to reorder them at WriteEntry time.
+	keys   []string
+	values [][]byte
+}
+
+// NewConsoleEncoder returns a new console encoder using the supplied
+// options.
+func NewConsoleEncoder(opts ConsoleEncoderOptions) *consoleEncoder {
+	if opts.TimeFormat == "" {
+		opts.TimeFormat = _defaultConsoleTimeFormat
+	}
+	enc := consolePool.Get().(*consoleEncoder)
+	enc.truncate()
+	enc.opts = opts
+	return enc
+}
+
+func (enc *consoleEncoder) truncate() {
+	enc.bytes = enc.bytes[:0]
+	enc.keys = enc.keys[:0]
+	enc.values = enc.values[:0]
+}
+
+// Clone copies the current encoder, including any data already encoded.
+func (enc *consoleEncoder) Clone() Encoder {
+	clone := consolePool.Get().(*consoleEncoder)
+	clone.truncate()
+	clone.opts = enc.opts
+	clone.keys = append(clone.keys, enc.keys...)
+	for _, v := range enc.values {
+		clone.values = append(clone.values, append([]byte{}, v...))
+	}
+	return clone
+}
+
+func (enc *consoleEncoder) Free() {
+	consolePool.Put(enc)
+}
+
+func (enc *consoleEncoder) useColor() bool {
+	if enc.opts.Color != nil {
+		return *enc.opts.Color
+	}
+	return isTerminal(os.Stderr)
+}
+
+func (enc *consoleEncoder) colorForLevel(lvl Level) string {
+	switch lvl {
+	case DebugLevel:
+		return _ansiCyan
+	case InfoLevel:
+		return _ansiGreen
+	case WarnLevel:
+		return _ansiYellow
+	case ErrorLevel, PanicLevel, FatalLevel:
+		return _ansiRed
+	default:
+		return ""
+	}
+}
+
+func (enc *consoleEncoder) levelString(lvl Level) string {
+	switch lvl {
+	case DebugLevel:
+		return "DEBUG"
+	case InfoLevel:
+		return "INFO"
+	case WarnLevel:
+		return "WARN"
+	case ErrorLevel:
+		return "ERROR"
+	case PanicLevel:
+		return "PANIC"
+	case FatalLevel:
+		return "FATAL"
+	default:
+		return fmt.Sprintf("LEVEL(%d)", lvl)
+	}
+}
+
+// WriteEntry writes a complete, human-formatted log line: a colorized
+// level tag, a humanized timestamp, the bold message, then key=value
+// pairs ordered per KeyOrder. It doesn't modify or lock the encoder's
+// accumulated fields, so it's safe to call from multiple goroutines, but
+// it's not safe to call WriteEntry while adding fields.
+func (enc *consoleEncoder) WriteEntry(sink io.Writer, msg string, lvl Level, t time.Time) error {
+	color := enc.useColor()
+	final := consolePool.Get().(*consoleEncoder)
+	final.truncate()
+
+	if color {
+		final.bytes = append(final.bytes, enc.colorForLevel(lvl)...)
+	}
+	final.bytes = append(final.bytes, enc.levelString(lvl)...)
+	if color {
+		final.bytes = append(final.bytes, _ansiReset...)
+	}
+	final.bytes = append(final.bytes, '\t')
+	final.bytes = t.AppendFormat(final.bytes, enc.opts.TimeFormat)
+	final.bytes = append(final.bytes, '\t')
+	if color {
+		final.bytes = append(final.bytes, _ansiBold...)
+	}
+	final.bytes = append(final.bytes, msg...)
+	if color {
+		final.bytes = append(final.bytes, _ansiReset...)
+	}
+
+	for _, i := range enc.orderedIndices() {
+		final.bytes = append(final.bytes, '\t')
+		final.appendKeyval(enc.keys[i], enc.values[i])
+	}
+	final.bytes = append(final.bytes, '\n')
+
+	expectedBytes := len(final.bytes)
+	n, err := sink.Write(final.bytes)
+	final.Free()
+	if err != nil {
+		return err
+	}
+	if n != expectedBytes {
+		return fmt.Errorf("incomplete write: only wrote %v of %v bytes", n, expectedBytes)
+	}
+	return nil
+}
+
+// orderedIndices returns indices into enc.keys/enc.values with any
+// KeyOrder entries moved to the front, in the order requested; fields not
+// named in KeyOrder keep their original relative order afterward.
+func (enc *consoleEncoder) orderedIndices() []int {
+	seen := make(map[int]bool, len(enc.keys))
+	ordered := make([]int, 0, len(enc.keys))
+
+	for _, want := range enc.opts.KeyOrder {
+		for i, key := range enc.keys {
+			if key == want && !seen[i] {
+				ordered = append(ordered, i)
+				seen[i] = true
+			}
+		}
+	}
+	for i := range enc.keys {
+		if !seen[i] {
+			ordered = append(ordered, i)
+			seen[i] = true
+		}
+	}
+	return ordered
+}
+
+func (enc *consoleEncoder) appendKeyval(key string, value []byte) {
+	enc.bytes = append(enc.bytes, key...)
+	if value == nil {
+		return
+	}
+	enc.bytes = append(enc.bytes, '=')
+	enc.bytes = append(enc.bytes, value...)
+}
+
+// addField appends value (already formatted, and quoted/escaped by the
+// caller if needed) under key, recording it in field order for KeyOrder to
+// consult later.
+func (enc *consoleEncoder) addField(key string, value []byte) {
+	enc.keys = append(enc.keys, key)
+	enc.values = append(enc.values, value)
+}
+
+// addFieldFromLogfmt records the value logfmtEncoder wrote for key into
+// tmp.bytes, copying it out first since tmp's buffer gets reused (and
+// overwritten) as soon as the caller frees it. The value starts after the
+// first '=': it can't be found by assuming the key is len(key) bytes long,
+// since addKey expands any non-ident byte in the key (space, '=', '"')
+// into the 6-byte `\ufffd` escape, and none of the scalar Add* methods on
+// logfmtEncoder ever emit a literal '=' before that separator.
+func (enc *consoleEncoder) addFieldFromLogfmt(key string, tmp *logfmtEncoder) {
+	i := bytes.IndexByte(tmp.bytes, '=')
+	value := tmp.bytes[i+1:]
+	enc.addField(key, append([]byte{}, value...))
+}
+
+// AddBool appends the key and bool value (bare "key" for true, matching
+// logfmt, or "key=false").
+func (enc *consoleEncoder) AddBool(key string, value bool) {
+	if value {
+		enc.addField(key, nil)
+		return
+	}
+	enc.addField(key, []byte("false"))
+}
+
+// AddFloat64 appends the key and float64 value to the encoder's fields.
+func (enc *consoleEncoder) AddFloat64(key string, value float64) {
+	tmp := newLogfmtEncoder()
+	tmp.AddFloat64(key, value)
+	enc.addFieldFromLogfmt(key, tmp)
+	tmp.Free()
+}
+
+// AddInt appends the key and int value to the encoder's fields.
+func (enc *consoleEncoder) AddInt(key string, value int) {
+	enc.AddInt64(key, int64(value))
+}
+
+// AddInt64 appends the key and int64 value to the encoder's fields.
+func (enc *consoleEncoder) AddInt64(key string, value int64) {
+	tmp := newLogfmtEncoder()
+	tmp.AddInt64(key, value)
+	enc.addFieldFromLogfmt(key, tmp)
+	tmp.Free()
+}
+
+// AddUint appends the key and uint value to the encoder's fields.
+func (enc *consoleEncoder) AddUint(key string, value uint) {
+	enc.AddUint64(key, uint64(value))
+}
+
+// AddUint64 appends the key and uint64 value to the encoder's fields.
+func (enc *consoleEncoder) AddUint64(key string, value uint64) {
+	tmp := newLogfmtEncoder()
+	tmp.AddUint64(key, value)
+	enc.addFieldFromLogfmt(key, tmp)
+	tmp.Free()
+}
+
+// AddUintptr appends the key and uintptr value to the encoder's fields.
+func (enc *consoleEncoder) AddUintptr(key string, value uintptr) {
+	tmp := newLogfmtEncoder()
+	tmp.AddUintptr(key, value)
+	enc.addFieldFromLogfmt(key, tmp)
+	tmp.Free()
+}
+
+// AddMarshaler adds a LogMarshaler to the encoder's fields. As with
+// logfmtEncoder, there's no real nesting, so we trust the LogMarshaler to
+// "do the right thing".
+func (enc *consoleEncoder) AddMarshaler(key string, obj LogMarshaler) error {
+	return obj.MarshalLog(enc)
+}
+
+// AddObject serializes value the same way logfmtEncoder does: as a quoted
+// JSON string.
+func (enc *consoleEncoder) AddObject(key string, value interface{}) error {
+	tmp := newLogfmtEncoder()
+	err := tmp.AddObject(key, value)
+	if err == nil {
+		enc.addFieldFromLogfmt(key, tmp)
+	}
+	tmp.Free()
+	return err
+}
+
+// AddString appends the key and string value, quoting and escaping it
+// (per the same rules as logfmtEncoder.AddString) only if it contains
+// spaces or other special bytes; plain identifiers are left bare so the
+// common case stays easy to read.
+func (enc *consoleEncoder) AddString(key, value string) {
+	if isPlainIdent(value) {
+		enc.addField(key, []byte(value))
+		return
+	}
+	tmp := newLogfmtEncoder()
+	tmp.AddString(key, value)
+	enc.addFieldFromLogfmt(key, tmp)
+	tmp.Free()
+}
+
+// isPlainIdent reports whether s can be written unquoted in logfmt: every
+// byte is an ident_byte (greater than ' ', excluding '=' and '"').
+func isPlainIdent(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if b := s[i]; b <= ' ' || b == '=' || b == '"' {
+			return false
+		}
+	}
+	return true
+}
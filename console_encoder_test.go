@@ -0,0 +1,84 @@
+package zap
+
+import (
+	"bytes"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func withConsoleEncoder(opts ConsoleEncoderOptions, f func(*consoleEncoder)) {
+	enc := NewConsoleEncoder(opts)
+	f(enc)
+	enc.Free()
+}
+
+func noColor() ConsoleEncoderOptions {
+	off := false
+	return ConsoleEncoderOptions{Color: &off}
+}
+
+func TestConsoleEncoderWriteEntryNoColor(t *testing.T) {
+	withConsoleEncoder(noColor(), func(enc *consoleEncoder) {
+		enc.AddString("caller", "foo.go:42")
+		enc.AddString("name with space", "needs quotes")
+
+		var buf bytes.Buffer
+		assert.NoError(t, enc.WriteEntry(&buf, "hello world", WarnLevel, time.Date(2016, 1, 1, 13, 14, 15, 123000000, time.UTC)))
+
+		expected := "WARN\t13:14:15.123\thello world\tcaller=foo.go:42\tname with space=\"needs quotes\"\n"
+		assert.Equal(t, expected, buf.String(), "Unexpected console output.")
+	})
+}
+
+func TestConsoleEncoderKeyOrder(t *testing.T) {
+	opts := noColor()
+	opts.KeyOrder = []string{"error", "caller"}
+
+	withConsoleEncoder(opts, func(enc *consoleEncoder) {
+		enc.AddString("other", "x")
+		enc.AddString("caller", "foo.go:1")
+		enc.AddString("error", "boom")
+
+		var buf bytes.Buffer
+		assert.NoError(t, enc.WriteEntry(&buf, "msg", ErrorLevel, time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)))
+
+		expected := "ERROR\t00:00:00.000\tmsg\terror=boom\tcaller=foo.go:1\tother=x\n"
+		assert.Equal(t, expected, buf.String(), "KeyOrder should surface error and caller first.")
+	})
+}
+
+func TestConsoleEncoderBool(t *testing.T) {
+	withConsoleEncoder(noColor(), func(enc *consoleEncoder) {
+		enc.AddBool("ok", true)
+		enc.AddBool("done", false)
+
+		var buf bytes.Buffer
+		assert.NoError(t, enc.WriteEntry(&buf, "m", InfoLevel, time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)))
+
+		expected := "INFO\t00:00:00.000\tm\tok\tdone=false\n"
+		assert.Equal(t, expected, buf.String(), "Lone true bools should be written bare, matching logfmt.")
+	})
+}
+
+func TestConsoleEncoderAddObjectError(t *testing.T) {
+	withConsoleEncoder(noColor(), func(enc *consoleEncoder) {
+		err := enc.AddObject("badobj", math.NaN())
+		assert.Error(t, err, "Expected an error marshaling a value JSON can't represent.")
+		assert.Empty(t, enc.keys, "A failed AddObject shouldn't leave a field behind.")
+		assert.Empty(t, enc.values, "A failed AddObject shouldn't leave a field behind.")
+	})
+}
+
+func TestConsoleEncoderColor(t *testing.T) {
+	on := true
+	enc := NewConsoleEncoder(ConsoleEncoderOptions{Color: &on})
+	var buf bytes.Buffer
+	assert.NoError(t, enc.WriteEntry(&buf, "m", ErrorLevel, time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)))
+	enc.Free()
+
+	assert.Contains(t, buf.String(), _ansiRed, "error level should be colored red.")
+	assert.Contains(t, buf.String(), _ansiBold, "message should be bold.")
+}
@@ -0,0 +1,18 @@
+// +build darwin freebsd netbsd openbsd dragonfly
+
+package zap
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// isTerminal reports whether f is connected to a terminal: the BSD family
+// (including macOS) answers the same TIOCGETA ioctl Linux answers via
+// TCGETS.
+func isTerminal(f *os.File) bool {
+	var termios syscall.Termios
+	_, _, err := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), syscall.TIOCGETA, uintptr(unsafe.Pointer(&termios)))
+	return err == 0
+}
@@ -0,0 +1,18 @@
+// +build linux
+
+package zap
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// isTerminal reports whether f is connected to a terminal, the same way
+// the standard isatty(3) check works: asking the kernel to describe the
+// fd's termios settings (TCGETS) and checking whether it succeeds.
+func isTerminal(f *os.File) bool {
+	var termios syscall.Termios
+	_, _, err := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), syscall.TCGETS, uintptr(unsafe.Pointer(&termios)))
+	return err == 0
+}
@@ -0,0 +1,12 @@
+// +build !linux,!darwin,!freebsd,!netbsd,!openbsd,!dragonfly
+
+package zap
+
+import "os"
+
+// isTerminal always reports false on platforms we don't have an ioctl-based
+// check for (notably Windows); callers should pass ConsoleEncoderOptions.Color
+// explicitly to force color on those platforms.
+func isTerminal(f *os.File) bool {
+	return false
+}
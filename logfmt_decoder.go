@@ -0,0 +1,218 @@
+package zap
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+)
+
+// LogfmtDecoder scans logfmt records produced by logfmtEncoder (or anything
+// following the same grammar), one key/value pair at a time. It follows the
+// EBNF quoted in logfmt_encoder.go verbatim, so it stays a faithful inverse
+// of AddString/AddBool/etc.'s escaping. The one lossy case is a byte of
+// invalid UTF-8, which AddString writes as the \u escape of U+FFFD: it decodes as U+FFFD,
+// not as the original byte.
+//
+// Typical use:
+//
+//	dec := NewLogfmtDecoder(r)
+//	for dec.ScanRecord() {
+//		for dec.ScanKeyval() {
+//			key, value := dec.Key(), dec.Value()
+//			// value is nil for a lone key (interpreted as boolean true)
+//		}
+//	}
+//	if err := dec.Err(); err != nil {
+//		// handle
+//	}
+type LogfmtDecoder struct {
+	r   *bufio.Reader
+	err error
+
+	key   []byte
+	value []byte
+
+	// sawByte holds a byte read while looking ahead that belongs to the
+	// next call (ScanRecord/ScanKeyval read one byte past what they
+	// consume in order to know when to stop).
+	sawByte  byte
+	hasByte  bool
+	atRecEnd bool
+}
+
+// NewLogfmtDecoder returns a LogfmtDecoder that reads records from r.
+func NewLogfmtDecoder(r io.Reader) *LogfmtDecoder {
+	return &LogfmtDecoder{r: bufio.NewReader(r)}
+}
+
+// Key returns the key decoded by the most recent successful ScanKeyval.
+func (d *LogfmtDecoder) Key() []byte {
+	return d.key
+}
+
+// Value returns the value decoded by the most recent successful
+// ScanKeyval. It is nil for a lone key, which logfmt (and AddBool) treats
+// as boolean true.
+func (d *LogfmtDecoder) Value() []byte {
+	return d.value
+}
+
+// Err returns the first error encountered while scanning, or nil if none
+// occurred (io.EOF is not reported here; ScanRecord and ScanKeyval simply
+// return false at end of input).
+func (d *LogfmtDecoder) Err() error {
+	return d.err
+}
+
+func (d *LogfmtDecoder) readByte() (byte, bool) {
+	if d.hasByte {
+		d.hasByte = false
+		return d.sawByte, true
+	}
+	b, err := d.r.ReadByte()
+	if err != nil {
+		if err != io.EOF {
+			d.err = err
+		}
+		return 0, false
+	}
+	return b, true
+}
+
+func (d *LogfmtDecoder) unreadByte(b byte) {
+	d.sawByte = b
+	d.hasByte = true
+}
+
+func isIdentByte(b byte) bool {
+	return b > ' ' && b != '=' && b != '"'
+}
+
+// ScanRecord reports whether another record is available. Call ScanKeyval
+// in a loop to read its pairs; the '\n' that ends a record is consumed by
+// ScanKeyval, and ScanRecord does not skip pairs of the previous record
+// that were left unread.
+func (d *LogfmtDecoder) ScanRecord() bool {
+	if d.err != nil {
+		return false
+	}
+	b, ok := d.readByte()
+	if !ok {
+		return false
+	}
+	d.unreadByte(b)
+	d.atRecEnd = false
+	return true
+}
+
+// ScanKeyval reads the next key/value pair of the current record. It
+// returns false when the record ends ('\n' is consumed) or input is
+// exhausted; call Err to check whether it stopped on a read error.
+func (d *LogfmtDecoder) ScanKeyval() bool {
+	if d.err != nil || d.atRecEnd {
+		return false
+	}
+
+	// skip garbage: bytes <= ' ', '=', or '"', per the grammar. '\n' ends
+	// the record rather than being skipped as garbage.
+	var b byte
+	var ok bool
+	for {
+		b, ok = d.readByte()
+		if !ok {
+			d.atRecEnd = true
+			return false
+		}
+		if b == '\n' {
+			d.atRecEnd = true
+			return false
+		}
+		if isIdentByte(b) {
+			break
+		}
+	}
+
+	d.key = d.key[:0]
+	for isIdentByte(b) {
+		d.key = append(d.key, b)
+		b, ok = d.readByte()
+		if !ok {
+			d.value = nil
+			return true
+		}
+	}
+
+	if b != '=' {
+		d.unreadByte(b)
+		d.value = nil
+		return true
+	}
+	// A key followed by '=' always has a non-nil value, even an empty one.
+	if d.value == nil {
+		d.value = []byte{}
+	}
+
+	b, ok = d.readByte()
+	if !ok {
+		d.value = []byte{}
+		return true
+	}
+
+	if b == '"' {
+		d.value = d.value[:0]
+		for {
+			b, ok = d.readByte()
+			if !ok {
+				return true
+			}
+			if b == '"' {
+				return true
+			}
+			if b != '\\' {
+				d.value = append(d.value, b)
+				continue
+			}
+			esc, ok := d.readByte()
+			if !ok {
+				return true
+			}
+			switch esc {
+			case 'n':
+				d.value = append(d.value, '\n')
+			case 'r':
+				d.value = append(d.value, '\r')
+			case 't':
+				d.value = append(d.value, '\t')
+			case '\\', '"':
+				d.value = append(d.value, esc)
+			case 'u':
+				// \uXXXX, as AddString writes (the \u escape of U+FFFD) for invalid UTF-8.
+				hex, _ := d.r.Peek(4)
+				if r, err := strconv.ParseUint(string(hex), 16, 32); err == nil && len(hex) == 4 {
+					d.r.Discard(4)
+					d.value = append(d.value, string(rune(r))...)
+				} else {
+					d.value = append(d.value, '\\', esc)
+				}
+			default:
+				// unrecognized escape: keep both bytes verbatim.
+				d.value = append(d.value, '\\', esc)
+			}
+		}
+	}
+
+	d.value = d.value[:0]
+	for isIdentByte(b) {
+		d.value = append(d.value, b)
+		b, ok = d.readByte()
+		if !ok {
+			return true
+		}
+	}
+	if b == '\n' {
+		d.atRecEnd = true
+		return true
+	}
+	d.unreadByte(b)
+	return true
+}
@@ -0,0 +1,92 @@
+package zap
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogfmtDecoderScanKeyval(t *testing.T) {
+	tests := []struct {
+		desc    string
+		in      string
+		keys    []string
+		values  []string
+		isBools []bool
+	}{
+		{"quoted string", `k="v"` + "\n", []string{"k"}, []string{"v"}, []bool{false}},
+		{"unquoted ident", "k=v\n", []string{"k"}, []string{"v"}, []bool{false}},
+		{"lone key", "k\n", []string{"k"}, []string{""}, []bool{true}},
+		{"multiple pairs", `a="1" b=2 c` + "\n", []string{"a", "b", "c"}, []string{"1", "2", ""}, []bool{false, false, true}},
+		{"escapes", `k="a\"b\\c\nd"` + "\n", []string{"k"}, []string{"a\"b\\c\nd"}, []bool{false}},
+		{"leading garbage", "  ==\"  k=v\n", []string{"k"}, []string{"v"}, []bool{false}},
+	}
+
+	for _, tt := range tests {
+		dec := NewLogfmtDecoder(bytes.NewBufferString(tt.in))
+		assert.True(t, dec.ScanRecord(), "%s: expected a record", tt.desc)
+
+		var keys, values []string
+		var isBool []bool
+		for dec.ScanKeyval() {
+			keys = append(keys, string(dec.Key()))
+			isBool = append(isBool, dec.Value() == nil)
+			values = append(values, string(dec.Value()))
+		}
+
+		assert.NoError(t, dec.Err(), tt.desc)
+		assert.Equal(t, tt.keys, keys, tt.desc)
+		assert.Equal(t, tt.values, values, tt.desc)
+		assert.Equal(t, tt.isBools, isBool, tt.desc)
+	}
+}
+
+func TestLogfmtDecoderRoundTrip(t *testing.T) {
+	enc := newLogfmtEncoder()
+	enc.AddString("k", "hello \"world\"\nwith\ttabs")
+	enc.AddBool("flag", true)
+	enc.AddInt64("count", -12)
+
+	var buf bytes.Buffer
+	assert.NoError(t, enc.WriteEntry(&buf, "msg here", InfoLevel, time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)))
+	enc.Free()
+
+	dec := NewLogfmtDecoder(&buf)
+	got := map[string]string{}
+	assert.True(t, dec.ScanRecord())
+	for dec.ScanKeyval() {
+		got[string(dec.Key())] = string(dec.Value())
+	}
+	assert.NoError(t, dec.Err())
+
+	assert.Equal(t, "info", got["level"])
+	assert.Equal(t, "msg here", got["msg"])
+	assert.Equal(t, "hello \"world\"\nwith\ttabs", got["k"])
+	assert.Equal(t, "-12", got["count"])
+}
+
+func FuzzLogfmtRoundTrip(f *testing.F) {
+	f.Add("a", "b")
+	f.Add("key", "value with spaces")
+	f.Add("key", "quotes \" and \\ backslashes")
+	f.Add("key", "newlines\nand\ttabs\rreturns")
+
+	f.Fuzz(func(t *testing.T, key, value string) {
+		if key == "" {
+			t.Skip("logfmt has no representation for an empty key")
+		}
+
+		enc := newLogfmtEncoder()
+		enc.AddString(key, value)
+
+		dec := NewLogfmtDecoder(bytes.NewReader(append(enc.bytes, '\n')))
+		enc.Free()
+
+		assert.True(t, dec.ScanRecord())
+		assert.True(t, dec.ScanKeyval())
+		assert.Equal(t, value, string(dec.Value()), "round-tripped value should match the original")
+		assert.NoError(t, dec.Err())
+	})
+}
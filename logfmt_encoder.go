@@ -1,7 +1,8 @@
 package zap
 
 import (
-	"errors"
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"math"
@@ -11,8 +12,6 @@ import (
 	"unicode/utf8"
 )
 
-var ErrAddObjNotImplemented = errors.New("AddObject is not implemented for logfmt encoder. Please consider implementing the LogMarshaler interface.")
-
 var logfmtPool = sync.Pool{
 	New: func() interface{} {
 		return &logfmtEncoder{
@@ -173,12 +172,83 @@ func (enc *logfmtEncoder) AddMarshaler(key string, obj LogMarshaler) error {
 	return obj.MarshalLog(enc)
 }
 
-// AddObject is not implemented for LogfmtEncoder
+// AddObject serializes value to a JSON string and emits it as a quoted
+// logfmt value (key="{\"a\":1,\"b\":[...]}"), borrowing the approach the
+// go-logfmt encoder uses for non-scalar values. The usual '\'/'"'/control-
+// byte escaping happens for free, since it's emitted through AddString.
 func (enc *logfmtEncoder) AddObject(key string, value interface{}) error {
-	enc.AddString(key, fmt.Sprintf("%+v", value))
+	marshaled, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	enc.AddString(key, string(marshaled))
 	return nil
 }
 
+// AddNested opens a child logfmt encoder, runs fn against it, and
+// serializes the child's accumulated k=v pairs as a JSON object under key.
+// LogMarshaler implementations can use this to opt into structured nesting
+// instead of flattening their sub-keys into the parent (which is what
+// AddMarshaler still does, since there's no way to know a LogMarshaler
+// wants nesting without asking it).
+func (enc *logfmtEncoder) AddNested(key string, fn func(Encoder) error) error {
+	child := newLogfmtEncoder()
+	err := fn(child)
+	nested := child.toJSONObject()
+	child.Free()
+	if err != nil {
+		return err
+	}
+
+	marshaled, err := json.Marshal(nested)
+	if err != nil {
+		return err
+	}
+	enc.AddString(key, string(marshaled))
+	return nil
+}
+
+// toJSONObject decodes the encoder's accumulated k=v pairs back into a
+// map[string]interface{} for embedding as a JSON object elsewhere. It
+// relies on LogfmtDecoder to stay the exact inverse of the Add* methods
+// above, and guesses each value's JSON type from its decoded text alone:
+// a lone key (Value() == nil, the same thing AddBool writes for true)
+// becomes the bool true, "true"/"false" become bools, and anything that
+// parses as a number becomes one, whether it was written quoted or not (so
+// AddString("k", "5") comes back as the number 5). Everything else stays a
+// string.
+func (enc *logfmtEncoder) toJSONObject() map[string]interface{} {
+	obj := map[string]interface{}{}
+	dec := NewLogfmtDecoder(bytes.NewReader(append(append([]byte{}, enc.bytes...), '\n')))
+	dec.ScanRecord()
+	for dec.ScanKeyval() {
+		obj[string(dec.Key())] = jsonValue(dec.Value())
+	}
+	return obj
+}
+
+// jsonValue recovers the JSON-typed value a LogfmtDecoder read, per the
+// rules documented on toJSONObject.
+func jsonValue(value []byte) interface{} {
+	if value == nil {
+		return true
+	}
+	s := string(value)
+	switch s {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return n
+	}
+	if n, err := strconv.ParseFloat(s, 64); err == nil {
+		return n
+	}
+	return s
+}
+
 // AddString adds a key and string value to the encoder's logline, escaping any '\' or '"' runes
 func (enc *logfmtEncoder) AddString(key, value string) {
 	enc.spacing()
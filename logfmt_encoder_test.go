@@ -63,12 +63,20 @@ func TestLogfmtEncoderFields(t *testing.T) {
 		{"marshaler", "k={}", func(e Encoder) {
 			assert.Error(t, e.AddMarshaler("k", loggable{false}), "Expected an error calling MarshalLog.")
 		}},
-		{"map[string]string", `k="map[loggable:yes]"`, func(e Encoder) {
+		{"map[string]string", `k="{\"loggable\":\"yes\"}"`, func(e Encoder) {
 			assert.NoError(t, e.AddObject("k", map[string]string{"loggable": "yes"}), "Unexpected error serializing a map.")
 		}},
-		{"arbitrary object", `k="{Name:jane}"`, func(e Encoder) {
+		{"arbitrary object", `k="{\"Name\":\"jane\"}"`, func(e Encoder) {
 			assert.NoError(t, e.AddObject("k", struct{ Name string }{"jane"}), "Unexpected error serializing a struct.")
 		}},
+		{"nested", `k="{\"a\":\"hi\",\"cnt\":5,\"flag\":true}"`, func(e Encoder) {
+			assert.NoError(t, e.(*logfmtEncoder).AddNested("k", func(nested Encoder) error {
+				nested.AddString("a", "hi")
+				nested.AddInt64("cnt", 5)
+				nested.AddBool("flag", true)
+				return nil
+			}), "Unexpected error serializing a nested encoder.")
+		}},
 	}
 
 	for _, tt := range tests {